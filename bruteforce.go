@@ -0,0 +1,209 @@
+package throttler
+
+import (
+	"sync"
+	"time"
+)
+
+// FailureStore is a Store that also tracks failed attempts and an
+// exponentially growing hold once a key has failed too many times, for
+// throttlers created with NewBruteforceThrottler. Fail, Success, and
+// BlockedUntil on Throttler are no-ops against a plain Store; they only do
+// something useful against a FailureStore.
+type FailureStore[K comparable] interface {
+	Store[K]
+	// Fail records a failed attempt for key at now, possibly putting key on
+	// hold if it has now failed too many times within the window.
+	Fail(key K, now time.Time)
+	// Success clears key's failure count and hold.
+	Success(key K)
+	// BlockedUntil reports the time key's hold expires, and whether key is
+	// currently on hold at all.
+	BlockedUntil(key K) (time.Time, bool)
+}
+
+// bruteforceEntry tracks one key's attempts and hold for bruteforceStore.
+type bruteforceEntry struct {
+	count        int
+	windowStart  time.Time
+	holdMultiple time.Duration
+	blockedUntil time.Time
+}
+
+// bruteforceStore is the in-process FailureStore used by
+// NewBruteforceThrottler. Once a key has failed more than maxAttempts times
+// within window, it is put on hold for initialHold, doubling on every further
+// failure, up to maxHold, until both the window and the hold have run out.
+type bruteforceStore[K comparable] struct {
+	mu          sync.Mutex
+	entries     map[K]*bruteforceEntry
+	maxAttempts int
+	window      time.Duration
+	initialHold time.Duration
+	maxHold     time.Duration
+}
+
+func newBruteforceStore[K comparable](maxAttempts int, window, initialHold, maxHold time.Duration) *bruteforceStore[K] {
+	return &bruteforceStore[K]{
+		entries:     make(map[K]*bruteforceEntry),
+		maxAttempts: maxAttempts,
+		window:      window,
+		initialHold: initialHold,
+		maxHold:     maxHold,
+	}
+}
+
+// Take reports whether key is currently on hold. Unlike the token-bucket
+// stores it doesn't count the call itself as an attempt; pair it with Fail
+// or Success once the caller knows whether the attempt succeeded.
+func (s *bruteforceStore[K]) Take(key K, now time.Time, n uint64) (allowed bool, remaining uint64, resetAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return true, uint64(s.maxAttempts), now
+	}
+
+	if now.Before(e.blockedUntil) {
+		return false, 0, e.blockedUntil
+	}
+
+	if now.Sub(e.windowStart) > s.window {
+		return true, uint64(s.maxAttempts), now
+	}
+
+	rem := s.maxAttempts - e.count
+	if rem < 0 {
+		rem = 0
+	}
+	return true, uint64(rem), now
+}
+
+func (s *bruteforceStore[K]) Set(key K, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &bruteforceEntry{
+		count:        s.maxAttempts + 1,
+		windowStart:  now,
+		holdMultiple: s.initialHold,
+		blockedUntil: now.Add(s.initialHold),
+	}
+}
+
+func (s *bruteforceStore[K]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func (s *bruteforceStore[K]) Sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.entries {
+		if now.After(e.blockedUntil) && now.Sub(e.windowStart) > s.window {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *bruteforceStore[K]) Close() error {
+	return nil
+}
+
+// Fail records a failed attempt for key at now.
+func (s *bruteforceStore[K]) Fail(key K, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || (now.Sub(e.windowStart) > s.window && !now.Before(e.blockedUntil)) {
+		e = &bruteforceEntry{count: 0, windowStart: now}
+		s.entries[key] = e
+	}
+
+	// Cap count at maxAttempts+1: that's enough to tell "still over the
+	// limit" on every subsequent Fail without letting it grow unbounded
+	// across a long-lived hold.
+	if e.count <= s.maxAttempts {
+		e.count++
+	}
+	if e.count <= s.maxAttempts {
+		return
+	}
+
+	// Key has now failed too many times: put it on hold, doubling the hold
+	// on every subsequent failure, capped at maxHold.
+	if e.holdMultiple == 0 {
+		e.holdMultiple = s.initialHold
+	} else {
+		e.holdMultiple *= 2
+		if e.holdMultiple > s.maxHold {
+			e.holdMultiple = s.maxHold
+		}
+	}
+	e.blockedUntil = now.Add(e.holdMultiple)
+}
+
+// Success clears key's failure count and hold.
+func (s *bruteforceStore[K]) Success(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// BlockedUntil reports the time key's hold expires, and whether key is
+// currently on hold at all.
+func (s *bruteforceStore[K]) BlockedUntil(key K) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	now := time.Now()
+	return e.blockedUntil, now.Before(e.blockedUntil)
+}
+
+// NewBruteforceThrottler creates a Throttler suited to guarding against
+// login or API abuse rather than smoothing out a steady rate: Allow(key)
+// reports whether key is currently on hold, and callers report the outcome
+// of each attempt via Fail or Success. Once a key has failed more than
+// maxAttempts times within window, it is put on hold for initialHold,
+// doubling on every further failure, up to maxHold, until both the window and
+// the hold have run out.
+func NewBruteforceThrottler[K comparable](maxAttempts int, window, initialHold, maxHold time.Duration) *Throttler[K] {
+	return NewWithStore[K](newBruteforceStore[K](maxAttempts, window, initialHold, maxHold))
+}
+
+// Fail records a failed attempt for key, for throttlers created with
+// NewBruteforceThrottler. It is a no-op on throttlers backed by a Store that
+// isn't also a FailureStore.
+func (t *Throttler[K]) Fail(key K) {
+	if fs, ok := t.store.(FailureStore[K]); ok {
+		fs.Fail(key, time.Now())
+	}
+}
+
+// Success clears key's failure count and hold, for throttlers created with
+// NewBruteforceThrottler. It is a no-op on throttlers backed by a Store that
+// isn't also a FailureStore.
+func (t *Throttler[K]) Success(key K) {
+	if fs, ok := t.store.(FailureStore[K]); ok {
+		fs.Success(key)
+	}
+}
+
+// BlockedUntil reports the time key's hold expires, and whether key is
+// currently on hold at all, for throttlers created with
+// NewBruteforceThrottler. It always returns false on throttlers backed by a
+// Store that isn't also a FailureStore.
+func (t *Throttler[K]) BlockedUntil(key K) (time.Time, bool) {
+	fs, ok := t.store.(FailureStore[K])
+	if !ok {
+		return time.Time{}, false
+	}
+	return fs.BlockedUntil(key)
+}
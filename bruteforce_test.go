@@ -0,0 +1,69 @@
+package throttler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBruteforceRemainingClampsAtZero(t *testing.T) {
+	store := newBruteforceStore[string](3, time.Minute, time.Second, time.Minute)
+	now := time.Now()
+
+	for i := 0; i < 5; i++ {
+		store.Fail("key", now)
+	}
+
+	allowed, remaining, resetAt := store.Take("key", now, 1)
+	if allowed {
+		t.Fatal("Take should report the key as blocked right after it tripped the hold")
+	}
+	if remaining != 0 {
+		t.Fatalf("remaining = %d while blocked, want 0", remaining)
+	}
+
+	// The 4th failure trips the hold at initialHold (1s); the 5th, while
+	// still blocked, doubles it to 2s.
+	after := now.Add(2*time.Second + time.Millisecond)
+	allowed, remaining, _ = store.Take("key", after, 1)
+	if !allowed {
+		t.Fatalf("Take should be allowed again once the hold expires, blocked until %v", resetAt)
+	}
+	if remaining != 0 {
+		t.Fatalf("remaining = %d right after a 5-failure trip of a 3-attempt budget, want 0 (not underflowed)", remaining)
+	}
+}
+
+func TestBruteforceSuccessResetsCounter(t *testing.T) {
+	store := newBruteforceStore[string](3, time.Minute, time.Second, time.Minute)
+	now := time.Now()
+
+	store.Fail("key", now)
+	store.Fail("key", now)
+	store.Success("key")
+
+	allowed, remaining, _ := store.Take("key", now, 1)
+	if !allowed {
+		t.Fatal("Take should be allowed after Success resets the key")
+	}
+	if remaining != 3 {
+		t.Fatalf("remaining = %d right after Success, want 3", remaining)
+	}
+}
+
+func TestBruteforceHoldDoublesWhileBlocked(t *testing.T) {
+	store := newBruteforceStore[string](1, time.Minute, time.Second, 10*time.Second)
+	now := time.Now()
+
+	store.Fail("key", now) // attempt 1: within budget, no hold yet
+	store.Fail("key", now) // attempt 2: trips the hold, blockedUntil = now+1s
+	blockedUntil, blocked := store.BlockedUntil("key")
+	if !blocked || !blockedUntil.Equal(now.Add(time.Second)) {
+		t.Fatalf("blockedUntil = %v, blocked = %v, want now+1s, true", blockedUntil, blocked)
+	}
+
+	store.Fail("key", now) // still blocked: hold doubles to 2s
+	blockedUntil, blocked = store.BlockedUntil("key")
+	if !blocked || !blockedUntil.Equal(now.Add(2*time.Second)) {
+		t.Fatalf("blockedUntil = %v, blocked = %v, want now+2s, true", blockedUntil, blocked)
+	}
+}
@@ -0,0 +1,105 @@
+package throttler
+
+import (
+	"context"
+	"sync"
+)
+
+// concurrencySlot is a per-key semaphore for ConcurrencyLimiter, reference
+// counted so idle keys can be dropped from the map as soon as nothing holds
+// them, rather than waiting on a sweeper.
+type concurrencySlot[K comparable] struct {
+	ch   chan struct{}
+	refs int
+}
+
+// ConcurrencyLimiter caps the number of in-flight operations per key, as
+// opposed to Throttler which caps how often operations may start. It
+// complements Throttler for callers that need to bound goroutine fan-out per
+// user or tenant rather than requests per second.
+type ConcurrencyLimiter[K comparable] struct {
+	mu            sync.Mutex
+	slots         map[K]*concurrencySlot[K]
+	maxConcurrent int
+}
+
+// NewConcurrencyLimiter creates a ConcurrencyLimiter that allows at most
+// maxConcurrent in-flight operations for each key.
+func NewConcurrencyLimiter[K comparable](maxConcurrent int) *ConcurrencyLimiter[K] {
+	return &ConcurrencyLimiter[K]{
+		slots:         make(map[K]*concurrencySlot[K]),
+		maxConcurrent: maxConcurrent,
+	}
+}
+
+// Acquire blocks until key is under its concurrency limit, or ctx is
+// cancelled, in which case it returns ctx.Err(). On success it returns a
+// release function that the caller must call exactly once to free the slot.
+func (l *ConcurrencyLimiter[K]) Acquire(ctx context.Context, key K) (release func(), err error) {
+	slot := l.acquireSlot(key)
+
+	select {
+	case slot.ch <- struct{}{}:
+		return l.releaseFunc(key, slot), nil
+	case <-ctx.Done():
+		l.releaseSlot(key, slot)
+		return nil, ctx.Err()
+	}
+}
+
+// TryAcquire reports whether key is currently under its concurrency limit.
+// If it is, a slot is taken and a release function is returned that the
+// caller must call exactly once to free it; otherwise it never blocks and ok
+// is false.
+func (l *ConcurrencyLimiter[K]) TryAcquire(key K) (release func(), ok bool) {
+	slot := l.acquireSlot(key)
+
+	select {
+	case slot.ch <- struct{}{}:
+		return l.releaseFunc(key, slot), true
+	default:
+		l.releaseSlot(key, slot)
+		return nil, false
+	}
+}
+
+// acquireSlot returns key's semaphore, creating it if necessary, and bumps
+// its reference count so it isn't garbage-collected out from under the
+// caller before they can use it.
+func (l *ConcurrencyLimiter[K]) acquireSlot(key K) *concurrencySlot[K] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slot, ok := l.slots[key]
+	if !ok {
+		slot = &concurrencySlot[K]{ch: make(chan struct{}, l.maxConcurrent)}
+		l.slots[key] = slot
+	}
+	slot.refs++
+	return slot
+}
+
+// releaseSlot drops a reference taken by acquireSlot, deleting key's
+// semaphore once nothing references it any more.
+func (l *ConcurrencyLimiter[K]) releaseSlot(key K, slot *concurrencySlot[K]) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slot.refs--
+	if slot.refs == 0 {
+		delete(l.slots, key)
+	}
+}
+
+// releaseFunc returns the release callback handed back from Acquire and
+// TryAcquire: it frees the semaphore slot itself, then drops the reference
+// taken by acquireSlot.
+func (l *ConcurrencyLimiter[K]) releaseFunc(key K, slot *concurrencySlot[K]) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-slot.ch
+			l.releaseSlot(key, slot)
+		})
+	}
+}
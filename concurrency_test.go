@@ -0,0 +1,99 @@
+package throttler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTryAcquireBlocksAtMaxConcurrent(t *testing.T) {
+	l := NewConcurrencyLimiter[string](2)
+
+	release1, ok := l.TryAcquire("key")
+	if !ok {
+		t.Fatal("1st TryAcquire should succeed")
+	}
+	release2, ok := l.TryAcquire("key")
+	if !ok {
+		t.Fatal("2nd TryAcquire should succeed")
+	}
+
+	if _, ok := l.TryAcquire("key"); ok {
+		t.Fatal("3rd TryAcquire should fail once maxConcurrent (2) is in flight")
+	}
+
+	release1()
+
+	release3, ok := l.TryAcquire("key")
+	if !ok {
+		t.Fatal("TryAcquire should succeed again once a slot is released")
+	}
+
+	release2()
+	release3()
+}
+
+func TestTryAcquireIsPerKey(t *testing.T) {
+	l := NewConcurrencyLimiter[string](1)
+
+	release, ok := l.TryAcquire("a")
+	if !ok {
+		t.Fatal("TryAcquire for key \"a\" should succeed")
+	}
+	defer release()
+
+	if _, ok := l.TryAcquire("b"); !ok {
+		t.Fatal("TryAcquire for a different key \"b\" should not be limited by \"a\"'s in-flight slot")
+	}
+}
+
+func TestAcquireBlocksUntilRelease(t *testing.T) {
+	l := NewConcurrencyLimiter[string](1)
+
+	release, ok := l.TryAcquire("key")
+	if !ok {
+		t.Fatal("TryAcquire should succeed")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		r, err := l.Acquire(context.Background(), "key")
+		if err != nil {
+			t.Errorf("Acquire returned %v, want nil", err)
+			return
+		}
+		r()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Acquire should still be blocked while the only slot is held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Acquire should have unblocked once the slot was released")
+	}
+}
+
+func TestAcquireRespectsContextCancellation(t *testing.T) {
+	l := NewConcurrencyLimiter[string](1)
+
+	release, ok := l.TryAcquire("key")
+	if !ok {
+		t.Fatal("TryAcquire should succeed")
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.Acquire(ctx, "key"); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire returned %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,105 @@
+// Package memstore is the in-process throttler.Store implementation used by
+// throttler.New and throttler.NewTokenBucket. It's exposed as its own
+// package so it can also be built directly via throttler.NewWithStore, or
+// composed with a caller's own caching layer.
+//
+// This package intentionally doesn't import throttler: its exported methods
+// already match the throttler.Store[K] interface by name, so the store it
+// returns satisfies that interface structurally wherever it's assigned to
+// one. Not importing throttler here is what lets throttler import memstore
+// for its own default constructors without an import cycle.
+package memstore
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket holds the token-bucket accounting for a single key.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type store[K comparable] struct {
+	mu      sync.Mutex
+	entries map[K]*bucket
+	rate    float64
+	burst   uint64
+	cleanup time.Duration
+}
+
+// New returns a Store (in the sense of throttler.Store[K]) that keeps all
+// state in process memory, refilling burst tokens at rate tokens per second
+// for each key independently. Entries that have been idle for cleanup are
+// evicted by Sweep.
+func New[K comparable](rate float64, burst uint64, cleanup time.Duration) *store[K] {
+	return &store[K]{
+		entries: make(map[K]*bucket),
+		rate:    rate,
+		burst:   burst,
+		cleanup: cleanup,
+	}
+}
+
+func (s *store[K]) Take(key K, now time.Time, n uint64) (allowed bool, remaining uint64, resetAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.refillLocked(key, now)
+
+	need := float64(n)
+	if b.tokens < need {
+		deficit := need - b.tokens
+		return false, uint64(b.tokens), now.Add(time.Duration(deficit / s.rate * float64(time.Second)))
+	}
+
+	b.tokens -= need
+	return true, uint64(b.tokens), now
+}
+
+func (s *store[K]) Set(key K, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &bucket{tokens: 0, last: now}
+}
+
+func (s *store[K]) Delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func (s *store[K]) Sweep(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, b := range s.entries {
+		if now.Sub(b.last) > s.cleanup {
+			delete(s.entries, key)
+		}
+	}
+}
+
+func (s *store[K]) Close() error {
+	return nil
+}
+
+// refillLocked returns the up-to-date bucket for key as of now, creating it
+// if necessary. Callers must hold s.mu.
+func (s *store[K]) refillLocked(key K, now time.Time) *bucket {
+	b, ok := s.entries[key]
+	if !ok {
+		b = &bucket{tokens: float64(s.burst), last: now}
+		s.entries[key] = b
+		return b
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	if refilled := b.tokens + elapsed*s.rate; refilled < float64(s.burst) {
+		b.tokens = refilled
+	} else {
+		b.tokens = float64(s.burst)
+	}
+	b.last = now
+	return b
+}
@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTakeRefillsOverTime(t *testing.T) {
+	s := New[string](2, 3, time.Minute) // 3 burst, refilling 2/s
+
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := s.Take("key", now, 1); !allowed {
+			t.Fatalf("Take #%d of the initial burst should be allowed", i+1)
+		}
+	}
+
+	if allowed, remaining, _ := s.Take("key", now, 1); allowed || remaining != 0 {
+		t.Fatalf("Take with an exhausted bucket = (%v, %d), want (false, 0)", allowed, remaining)
+	}
+
+	// Half a second at 2 tokens/s refills exactly 1 token.
+	half := now.Add(500 * time.Millisecond)
+	if allowed, remaining, _ := s.Take("key", half, 1); !allowed || remaining != 0 {
+		t.Fatalf("Take after a 1-token refill = (%v, %d), want (true, 0)", allowed, remaining)
+	}
+
+	// Refill never exceeds burst, however long elapses.
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := s.Take("key", later, 1); !allowed {
+			t.Fatalf("Take #%d after a long idle period should be allowed up to burst", i+1)
+		}
+	}
+	if allowed, _, _ := s.Take("key", later, 1); allowed {
+		t.Fatal("Take beyond burst should still be denied regardless of how long elapsed")
+	}
+}
+
+func TestTakeReportsResetAt(t *testing.T) {
+	s := New[string](1, 1, time.Minute) // 1/s, burst 1
+
+	now := time.Now()
+	if allowed, _, _ := s.Take("key", now, 1); !allowed {
+		t.Fatal("first Take should be allowed")
+	}
+
+	allowed, _, resetAt := s.Take("key", now, 1)
+	if allowed {
+		t.Fatal("second immediate Take should be denied")
+	}
+	if want := now.Add(time.Second); !resetAt.Equal(want) {
+		t.Fatalf("resetAt = %v, want %v", resetAt, want)
+	}
+}
+
+func TestSweepEvictsIdleEntries(t *testing.T) {
+	s := New[string](1, 1, time.Second)
+
+	now := time.Now()
+	s.Take("stale", now, 1)
+	s.Take("fresh", now, 1)
+
+	later := now.Add(2 * time.Second)
+	s.Take("fresh", later, 1) // touches "fresh", leaving "stale" idle
+	s.Sweep(later)
+
+	if _, ok := s.entries["stale"]; ok {
+		t.Fatal("Sweep should have evicted the idle \"stale\" entry")
+	}
+	if _, ok := s.entries["fresh"]; !ok {
+		t.Fatal("Sweep should not evict the recently touched \"fresh\" entry")
+	}
+}
@@ -0,0 +1,134 @@
+// Package redisstore is a throttler.Store backed by Redis, for throttling
+// that needs to be shared across replicas of a service rather than kept
+// per-process.
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vindolin/throttler"
+)
+
+// Client is the subset of a Redis client's API redisstore needs. It is
+// satisfied by a thin adapter over *redis.Client from
+// github.com/redis/go-redis/v9 (or any compatible client), so this package
+// does not have to depend on a specific Redis driver.
+type Client interface {
+	// Get returns the current value stored at key, or ("", false, nil) if
+	// key does not exist. Unlike GetSet, Get must not modify key.
+	Get(ctx context.Context, key string) (value string, existed bool, err error)
+	// GetSet atomically sets key to value and returns its previous value, or
+	// ("", false, nil) if key did not exist.
+	GetSet(ctx context.Context, key, value string) (previous string, existed bool, err error)
+	// Expire sets a TTL on key.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// Del removes key.
+	Del(ctx context.Context, key string) error
+}
+
+type store[K comparable] struct {
+	client  Client
+	prefix  string
+	rate    float64
+	burst   uint64
+	cleanup time.Duration
+}
+
+// New returns a throttler.Store that keeps bucket state in Redis under
+// keys prefix+fmt.Sprint(key), refilling burst tokens at rate tokens per
+// second for each key independently. Every Take call pushes out the key's
+// TTL to cleanup, so idle keys expire on their own; Sweep is a no-op.
+//
+// Take reads a key's encoded bucket state with Get and commits the refilled
+// result with a single GetSet; it never uses GetSet to peek, so a concurrent
+// reader never mistakes an in-flight update for a missing key and gets
+// handed a free burst. The read and the write are still two separate round
+// trips, so two callers racing on the exact same key can still lose one
+// update to the other and occasionally over-spend a token; for strict
+// atomicity under heavy contention, wrap a Lua-scripted client in Client
+// instead.
+func New[K comparable](client Client, prefix string, rate float64, burst uint64, cleanup time.Duration) throttler.Store[K] {
+	return &store[K]{
+		client:  client,
+		prefix:  prefix,
+		rate:    rate,
+		burst:   burst,
+		cleanup: cleanup,
+	}
+}
+
+func (s *store[K]) Take(key K, now time.Time, n uint64) (allowed bool, remaining uint64, resetAt time.Time) {
+	redisKey := s.redisKey(key)
+
+	tokens := float64(s.burst)
+	if previous, existed, err := s.client.Get(context.Background(), redisKey); err == nil && existed {
+		if t, last, ok := decode(previous); ok {
+			tokens = min(float64(s.burst), t+now.Sub(last).Seconds()*s.rate)
+		}
+	}
+
+	need := float64(n)
+	if tokens < need {
+		deficit := need - tokens
+		s.client.GetSet(context.Background(), redisKey, encode(tokens, now))
+		s.client.Expire(context.Background(), redisKey, s.cleanup)
+		return false, uint64(tokens), now.Add(time.Duration(deficit / s.rate * float64(time.Second)))
+	}
+
+	tokens -= need
+	s.client.GetSet(context.Background(), redisKey, encode(tokens, now))
+	s.client.Expire(context.Background(), redisKey, s.cleanup)
+	return true, uint64(tokens), now
+}
+
+func (s *store[K]) Set(key K, now time.Time) {
+	redisKey := s.redisKey(key)
+	s.client.GetSet(context.Background(), redisKey, encode(0, now))
+	s.client.Expire(context.Background(), redisKey, s.cleanup)
+}
+
+func (s *store[K]) Delete(key K) {
+	s.client.Del(context.Background(), s.redisKey(key))
+}
+
+// Sweep is a no-op: Redis expires keys on its own via the TTL set in Take
+// and Set.
+func (s *store[K]) Sweep(now time.Time) {}
+
+func (s *store[K]) Close() error {
+	return nil
+}
+
+func (s *store[K]) redisKey(key K) string {
+	return s.prefix + fmt.Sprint(key)
+}
+
+// encode packs a bucket's token count and last-refill time into the string
+// value stored in Redis.
+func encode(tokens float64, last time.Time) string {
+	return strconv.FormatFloat(tokens, 'f', -1, 64) + ":" + strconv.FormatInt(last.UnixNano(), 10)
+}
+
+// decode unpacks a string previously produced by encode.
+func decode(value string) (tokens float64, last time.Time, ok bool) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return 0, time.Time{}, false
+	}
+
+	tokens, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+
+	return tokens, time.Unix(0, nanos), true
+}
@@ -0,0 +1,103 @@
+package redisstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClient is an in-memory Client for tests. Its Get optionally sleeps
+// before reading the key, to simulate the round-trip latency a real Redis
+// client would have.
+type fakeClient struct {
+	mu     sync.Mutex
+	values map[string]string
+	delay  time.Duration
+}
+
+func newFakeClient(delay time.Duration) *fakeClient {
+	return &fakeClient{values: make(map[string]string), delay: delay}
+}
+
+func (c *fakeClient) Get(ctx context.Context, key string) (string, bool, error) {
+	time.Sleep(c.delay)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.values[key]
+	return v, ok, nil
+}
+
+func (c *fakeClient) GetSet(ctx context.Context, key, value string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.values[key]
+	c.values[key] = value
+	return previous, ok, nil
+}
+
+func (c *fakeClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return nil
+}
+
+func (c *fakeClient) Del(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.values, key)
+	return nil
+}
+
+// TestTakeDoesNotResetOnConcurrentRead guards against Take using GetSet as a
+// non-destructive peek: that clobbers the stored value mid-Take, so a
+// concurrent reader on the same key sees a missing key and is handed a full
+// fresh burst. With a non-destructive Get in place, a caller whose Get lands
+// after another caller's write sees the spent bucket, so only one of the
+// callers here should succeed. The gap between one caller's Get and its own
+// GetSet is not widened, so this does not exercise the read-then-write race
+// that New documents.
+func TestTakeDoesNotResetOnConcurrentRead(t *testing.T) {
+	client := newFakeClient(20 * time.Millisecond)
+	s := New[string](client, "throttle:", 1, 1, time.Minute)
+
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	allowedCount := make(chan bool, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, _, _ := s.Take("same-key", now, 1)
+			allowedCount <- allowed
+		}()
+	}
+	wg.Wait()
+	close(allowedCount)
+
+	allowed := 0
+	for a := range allowedCount {
+		if a {
+			allowed++
+		}
+	}
+
+	if allowed > 1 {
+		t.Fatalf("got %d allowed Take calls racing for a burst of 1, want at most 1", allowed)
+	}
+}
+
+func TestTakeRefillsOverTime(t *testing.T) {
+	client := newFakeClient(0)
+	s := New[string](client, "throttle:", 1, 1, time.Minute)
+
+	now := time.Now()
+	if allowed, _, _ := s.Take("key", now, 1); !allowed {
+		t.Fatal("first Take should be allowed")
+	}
+	if allowed, _, _ := s.Take("key", now, 1); allowed {
+		t.Fatal("second immediate Take should be denied")
+	}
+	if allowed, _, _ := s.Take("key", now.Add(time.Second), 1); !allowed {
+		t.Fatal("Take after a full second should be allowed again")
+	}
+}
@@ -0,0 +1,37 @@
+package throttler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewWithSweeperEvictsIdleKeys(t *testing.T) {
+	th := NewWithSweeper[string](time.Hour, 10*time.Millisecond, 20*time.Millisecond)
+	defer th.Close()
+
+	if !th.Allow("key") {
+		t.Fatal("first Allow should succeed")
+	}
+
+	// Don't touch "key" again until well past minTTL, so the sweeper (not
+	// this call) is responsible for evicting it.
+	time.Sleep(200 * time.Millisecond)
+
+	// An hour-long throttle with negligible natural refill would still deny
+	// this; getting true back means the background sweeper evicted the idle
+	// entry and "key" started fresh.
+	if !th.Allow("key") {
+		t.Fatal("Allow should succeed again once the sweeper has evicted the idle key")
+	}
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	th := NewWithSweeper[string](time.Minute, time.Millisecond, time.Millisecond)
+
+	if err := th.Close(); err != nil {
+		t.Fatalf("first Close returned %v, want nil", err)
+	}
+	if err := th.Close(); err != nil {
+		t.Fatalf("second Close returned %v, want nil", err)
+	}
+}
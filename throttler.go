@@ -1,60 +1,186 @@
+// Package throttler provides a generic, concurrency-safe rate throttler.
+//
+// Throttler itself only coordinates callers; where the per-key state lives
+// is delegated to a Store. The built-in constructors (New, NewTokenBucket)
+// use an in-process store, but any backend that satisfies Store can be
+// plugged in via NewWithStore, including ones that share state across a
+// cluster (see the redisstore sub-package; memstore holds the in-process
+// store).
 package throttler
 
 import (
+	"context"
 	"sync"
 	"time"
+
+	"github.com/vindolin/throttler/memstore"
 )
 
-// throttler is a concurrency-safe throttler that can be used to limit the rate
-// at which certain operations are performed. It uses a map to store the last
-// time each unique value was allowed.
-type throttler struct {
-	// mutex protects the valueMap
-	mu sync.Mutex
-	// valueMap maps each unique value to the last time it was allowed
-	valueMap map[interface{}]time.Time
-	// throttle is the minimum duration between allows for each value
-	throttle time.Duration
-	// cleanup is the interval at which expired entries are removed from the map
-	cleanup time.Duration
-	// lastClean is the last time the map was cleaned
-	lastClean time.Time
-}
-
-// NewThrottler creates a new NewThrottler that will allow at most one request every
-// throttle duration, and will expire entries after cleanup has passed.
-func NewThrottler(throttle, cleanup time.Duration) *throttler {
-	return &throttler{
-		valueMap:  make(map[interface{}]time.Time),
-		throttle:  throttle,
-		cleanup:   cleanup,
-		lastClean: time.Now(),
-	}
+// Store is the persistence backend behind a Throttler. Implementations must
+// be safe for concurrent use.
+type Store[K comparable] interface {
+	// Take records an attempt to spend n tokens for key at now. If enough
+	// tokens are available they are taken and allowed is true; otherwise the
+	// store is left untouched, allowed is false, and resetAt reports when
+	// enough tokens will next be available. remaining reports how many
+	// tokens are left for key after the call.
+	Take(key K, now time.Time, n uint64) (allowed bool, remaining uint64, resetAt time.Time)
+	// Set forces key into a fully-throttled state as of now, as if its
+	// tokens had just been exhausted.
+	Set(key K, now time.Time)
+	// Delete removes key from the store, as if it had never been seen.
+	Delete(key K)
+	// Sweep evicts entries that are stale as of now.
+	Sweep(now time.Time)
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Throttler is a concurrency-safe throttler that can be used to limit the
+// rate at which certain operations are performed, per key. The zero value is
+// not usable; construct one with New, NewTokenBucket, or NewWithStore.
+type Throttler[K comparable] struct {
+	store Store[K]
+
+	stopCh    chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// New creates a Throttler backed by an in-process store that will allow at
+// most one operation per key every throttle duration, expiring entries that
+// have been idle for cleanup.
+func New[K comparable](throttle, cleanup time.Duration) *Throttler[K] {
+	return NewWithStore[K](memstore.New[K](1/throttle.Seconds(), 1, cleanup))
+}
+
+// NewTokenBucket creates a Throttler backed by an in-process store that
+// allows, for each key, up to burst operations to happen back to back, after
+// which operations are allowed at rate tokens per second. Entries are
+// expired after cleanup has passed since they were last touched.
+func NewTokenBucket[K comparable](rate float64, burst uint64, cleanup time.Duration) *Throttler[K] {
+	return NewWithStore[K](memstore.New[K](rate, burst, cleanup))
+}
+
+// NewWithSweeper creates a Throttler exactly like New, except that instead of
+// relying on callers to trigger cleanup, a background goroutine calls the
+// store's Sweep every sweepInterval to evict keys idle for longer than
+// minTTL. This avoids the unbounded latency spike an opportunistic,
+// in-Allow cleanup would otherwise impose on whichever caller happens to
+// trigger it once the store has grown large. The goroutine runs until Close
+// is called.
+func NewWithSweeper[K comparable](throttle, sweepInterval, minTTL time.Duration) *Throttler[K] {
+	t := New[K](throttle, minTTL)
+	t.startSweeper(sweepInterval)
+	return t
+}
+
+// NewWithStore creates a Throttler backed by store, for callers that need a
+// backend other than the built-in in-process one, such as the shared store
+// from the redisstore sub-package.
+func NewWithStore[K comparable](store Store[K]) *Throttler[K] {
+	return &Throttler[K]{store: store, stopCh: make(chan struct{})}
 }
 
-// Allow checks if the given value is allowed to do an operation. If the value
-// is not present in the map or if the last time it was allowed is more than
-// throttle duration ago, the value is added to the map and true is returned.
-// Otherwise, false is returned.
-func (t *throttler) Allow(value interface{}) bool {
-	t.mu.Lock()
-	defer t.mu.Unlock()
+// startSweeper runs store.Sweep on a ticker until t is closed. The Store
+// interface doesn't expose how many entries it holds, so unlike a
+// size-aware sweeper this one always ticks rather than parking while the
+// store is empty; sweepInterval should be chosen accordingly.
+func (t *Throttler[K]) startSweeper(sweepInterval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(sweepInterval)
+		defer ticker.Stop()
 
-	// Clean up old entries if the cleanup interval has passed
-	if time.Since(t.lastClean) > t.cleanup {
-		for val, lastTime := range t.valueMap {
-			if time.Since(lastTime) > t.throttle {
-				delete(t.valueMap, val)
+		for {
+			select {
+			case <-t.stopCh:
+				return
+			case now := <-ticker.C:
+				t.store.Sweep(now)
 			}
 		}
-		t.lastClean = time.Now()
+	}()
+}
+
+// Allow reports whether key is allowed to perform one operation right now.
+// It is equivalent to AllowN(key, 1).
+func (t *Throttler[K]) Allow(key K) bool {
+	return t.AllowN(key, 1)
+}
+
+// AllowN reports whether key is allowed to spend n tokens right now. If it
+// is, they are taken and true is returned; otherwise the underlying store is
+// left untouched and false is returned.
+func (t *Throttler[K]) AllowN(key K, n uint64) bool {
+	allowed, _, _ := t.store.Take(key, time.Now(), n)
+	return allowed
+}
+
+// AllowDetail behaves like AllowN, but also reports how many further tokens
+// remain for key and when its tokens next reset, for callers that need to
+// surface that detail (such as an HTTP middleware setting rate-limit
+// headers).
+func (t *Throttler[K]) AllowDetail(key K, n uint64) (allowed bool, remaining uint64, resetAt time.Time) {
+	return t.store.Take(key, time.Now(), n)
+}
+
+// Reserve reports how long the caller would have to wait for n tokens to
+// become available for key. If they're available now, they're taken
+// immediately and Reserve returns (0, true); otherwise nothing is taken and
+// Reserve returns the wait instead. It is meant for callers that want to
+// schedule work rather than poll for it.
+func (t *Throttler[K]) Reserve(key K, n uint64) (delay time.Duration, ok bool) {
+	allowed, _, resetAt := t.store.Take(key, time.Now(), n)
+	if allowed {
+		return 0, true
 	}
+	return time.Until(resetAt), false
+}
+
+// Wait blocks until n tokens are available for key, taking them as soon as
+// they are, or until ctx is cancelled, in which case it returns ctx.Err().
+func (t *Throttler[K]) Wait(ctx context.Context, key K, n uint64) error {
+	for {
+		allowed, _, resetAt := t.store.Take(key, time.Now(), n)
+		if allowed {
+			return nil
+		}
 
-	// Check if request is allowed
-	if lastTime, ok := t.valueMap[value]; ok && time.Since(lastTime) < t.throttle {
-		return false
+		delay := time.Until(resetAt)
+		if delay <= 0 {
+			continue
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
 	}
+}
+
+// Delete removes key from the throttler, as if it had never been seen.
+func (t *Throttler[K]) Delete(key K) {
+	t.store.Delete(key)
+}
+
+// ForceThrottle puts key into a fully-throttled state immediately, as if it
+// had just exhausted its tokens, overriding whatever state it was in. It's
+// meant for callers that need to block a key outside the normal Allow/Take
+// flow, such as in response to an out-of-band abuse signal.
+func (t *Throttler[K]) ForceThrottle(key K) {
+	t.store.Set(key, time.Now())
+}
 
-	t.valueMap[value] = time.Now()
-	return true
+// Close stops the background sweeper, if one was started by
+// NewWithSweeper, and releases any resources held by the throttler's store.
+// Close is idempotent.
+func (t *Throttler[K]) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.stopCh)
+		t.closeErr = t.store.Close()
+	})
+	return t.closeErr
 }
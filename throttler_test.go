@@ -0,0 +1,36 @@
+package throttler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForceThrottle(t *testing.T) {
+	th := New[string](time.Minute, time.Minute)
+
+	if !th.Allow("key") {
+		t.Fatal("first Allow should succeed")
+	}
+
+	th.Delete("key")
+	th.ForceThrottle("key")
+
+	if th.Allow("key") {
+		t.Fatal("Allow right after ForceThrottle should be denied")
+	}
+}
+
+func TestForceThrottleBruteforce(t *testing.T) {
+	th := NewBruteforceThrottler[string](3, time.Minute, time.Second, time.Minute)
+
+	th.ForceThrottle("key")
+
+	if th.Allow("key") {
+		t.Fatal("Allow right after ForceThrottle should be denied on a bruteforce-backed throttler too")
+	}
+
+	blockedUntil, blocked := th.BlockedUntil("key")
+	if !blocked {
+		t.Fatalf("BlockedUntil should report key as blocked right after ForceThrottle, blockedUntil = %v", blockedUntil)
+	}
+}
@@ -0,0 +1,53 @@
+package throttlerhttp
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ByIP returns a key function that throttles by client IP address. It reads
+// the rightmost trustedProxies entries out of X-Forwarded-For before picking
+// the client IP, since those hops are assumed to be trusted proxies (such as
+// a load balancer) rather than the client itself. If the header is absent,
+// empty, or trustedProxies is 0, it falls back to r.RemoteAddr.
+func ByIP(trustedProxies int) func(*http.Request) string {
+	return func(r *http.Request) string {
+		if trustedProxies > 0 {
+			if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+				hops := strings.Split(xff, ",")
+				for i := range hops {
+					hops[i] = strings.TrimSpace(hops[i])
+				}
+
+				if idx := len(hops) - trustedProxies - 1; idx >= 0 {
+					return hops[idx]
+				}
+
+				return hops[0]
+			}
+		}
+
+		return r.RemoteAddr
+	}
+}
+
+// ByHeader returns a key function that throttles by the value of the named
+// request header, such as an API key.
+func ByHeader(name string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// ByCookie returns a key function that throttles by the value of the named
+// cookie. Requests without the cookie are all throttled together under the
+// empty-string key.
+func ByCookie(name string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		c, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return c.Value
+	}
+}
@@ -0,0 +1,73 @@
+package throttlerhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestByIPFallsBackToRemoteAddr(t *testing.T) {
+	keyFn := ByIP(0)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+
+	if got := keyFn(req); got != "203.0.113.1:1234" {
+		t.Fatalf("ByIP(0) = %q, want RemoteAddr", got)
+	}
+}
+
+func TestByIPUsesTrustedProxyHop(t *testing.T) {
+	keyFn := ByIP(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	req.Header.Set("X-Forwarded-For", "client, proxy1")
+
+	if got := keyFn(req); got != "client" {
+		t.Fatalf("ByIP(1) = %q, want %q", got, "client")
+	}
+}
+
+func TestByIPIgnoresMissingHeader(t *testing.T) {
+	keyFn := ByIP(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+
+	if got := keyFn(req); got != "203.0.113.1:1234" {
+		t.Fatalf("ByIP(1) with no X-Forwarded-For = %q, want RemoteAddr", got)
+	}
+}
+
+func TestByHeader(t *testing.T) {
+	keyFn := ByHeader("X-API-Key")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "abc123")
+
+	if got := keyFn(req); got != "abc123" {
+		t.Fatalf("ByHeader = %q, want %q", got, "abc123")
+	}
+}
+
+func TestByCookie(t *testing.T) {
+	keyFn := ByCookie("session")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "xyz"})
+
+	if got := keyFn(req); got != "xyz" {
+		t.Fatalf("ByCookie = %q, want %q", got, "xyz")
+	}
+}
+
+func TestByCookieMissingReturnsEmpty(t *testing.T) {
+	keyFn := ByCookie("session")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := keyFn(req); got != "" {
+		t.Fatalf("ByCookie with no cookie set = %q, want empty string", got)
+	}
+}
@@ -0,0 +1,79 @@
+// Package throttlerhttp adapts a throttler.Throttler into net/http
+// middleware, setting the standard RateLimit-* response headers and
+// supporting pluggable key extraction.
+package throttlerhttp
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/vindolin/throttler"
+)
+
+// config holds the options a Middleware was built with.
+type config struct {
+	limit    uint64
+	onDenied func(w http.ResponseWriter, r *http.Request, resetAt time.Time)
+}
+
+// Option configures a Middleware.
+type Option func(*config)
+
+// WithLimit sets the value reported in the RateLimit-Limit header. The
+// Throttler itself doesn't expose its burst size generically (a Store is
+// free to implement limits however it likes), so callers that want the
+// header populated must say what it is.
+func WithLimit(limit uint64) Option {
+	return func(c *config) {
+		c.limit = limit
+	}
+}
+
+// WithOnDenied sets a hook called instead of the default 429 response when a
+// request is throttled, for callers that want to return JSON, log, or emit
+// metrics.
+func WithOnDenied(fn func(w http.ResponseWriter, r *http.Request, resetAt time.Time)) Option {
+	return func(c *config) {
+		c.onDenied = fn
+	}
+}
+
+// Middleware returns net/http middleware that throttles requests by the key
+// keyFn extracts from each request, using t. Requests over the limit get a
+// 429 response (or whatever WithOnDenied is set to) with standard
+// RateLimit-Remaining, RateLimit-Reset, and Retry-After headers, plus
+// RateLimit-Limit when WithLimit is set.
+func Middleware(t *throttler.Throttler[string], keyFn func(*http.Request) string, opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, remaining, resetAt := t.AllowDetail(keyFn(r), 1)
+
+			header := w.Header()
+			if cfg.limit > 0 {
+				header.Set("RateLimit-Limit", strconv.FormatUint(cfg.limit, 10))
+			}
+			header.Set("RateLimit-Remaining", strconv.FormatUint(remaining, 10))
+			header.Set("RateLimit-Reset", strconv.FormatInt(int64(time.Until(resetAt).Seconds()+0.5), 10))
+
+			if allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Set("Retry-After", strconv.FormatInt(int64(time.Until(resetAt).Seconds()+0.5), 10))
+
+			if cfg.onDenied != nil {
+				cfg.onDenied(w, r, resetAt)
+				return
+			}
+
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		})
+	}
+}
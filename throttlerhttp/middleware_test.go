@@ -0,0 +1,92 @@
+package throttlerhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vindolin/throttler"
+)
+
+func TestMiddlewareAllowsWithinLimit(t *testing.T) {
+	th := throttler.NewTokenBucket[string](1, 1, time.Minute)
+	mw := Middleware(th, ByHeader("X-Key"), WithLimit(1))
+
+	called := false
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Key", "alice")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if !called {
+		t.Fatal("handler should have been called for a request within the limit")
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if got := rec.Header().Get("RateLimit-Limit"); got != "1" {
+		t.Fatalf("RateLimit-Limit = %q, want %q", got, "1")
+	}
+	if got := rec.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Fatalf("RateLimit-Remaining = %q, want %q", got, "0")
+	}
+}
+
+func TestMiddlewareDeniesOverLimit(t *testing.T) {
+	th := throttler.NewTokenBucket[string](1, 1, time.Minute)
+	mw := Middleware(th, ByHeader("X-Key"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Key", "bob")
+
+	passthrough := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	passthrough.ServeHTTP(httptest.NewRecorder(), req)
+
+	denying := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called once the key is throttled")
+	}))
+
+	rec := httptest.NewRecorder()
+	denying.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got == "" {
+		t.Fatal("Retry-After header should be set on a denied request")
+	}
+}
+
+func TestMiddlewareWithOnDenied(t *testing.T) {
+	th := throttler.NewTokenBucket[string](1, 1, time.Minute)
+
+	var hookCalled bool
+	mw := Middleware(th, ByHeader("X-Key"), WithOnDenied(func(w http.ResponseWriter, r *http.Request, resetAt time.Time) {
+		hookCalled = true
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Key", "carol")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !hookCalled {
+		t.Fatal("WithOnDenied hook should have been called instead of the default 429 response")
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 from the custom hook", rec.Code)
+	}
+}
@@ -0,0 +1,55 @@
+package throttler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReserve(t *testing.T) {
+	th := NewTokenBucket[string](1, 1, time.Minute) // 1/s, burst 1
+
+	if delay, ok := th.Reserve("key", 1); !ok || delay != 0 {
+		t.Fatalf("Reserve on an untouched key = (%v, %v), want (0, true)", delay, ok)
+	}
+
+	delay, ok := th.Reserve("key", 1)
+	if ok {
+		t.Fatal("immediate second Reserve should report ok = false")
+	}
+	if delay <= 0 || delay > time.Second {
+		t.Fatalf("delay = %v, want in (0, 1s]", delay)
+	}
+}
+
+func TestWaitTakesAssoonAsAvailable(t *testing.T) {
+	th := NewTokenBucket[string](10, 1, time.Minute) // 10/s, burst 1
+
+	if !th.Allow("key") {
+		t.Fatal("first Allow should succeed")
+	}
+
+	start := time.Now()
+	if err := th.Wait(context.Background(), "key", 1); err != nil {
+		t.Fatalf("Wait returned %v, want nil", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Wait took %v, want under 500ms (refill is 10/s, so about 100ms)", elapsed)
+	}
+}
+
+func TestWaitRespectsContextCancellation(t *testing.T) {
+	th := NewTokenBucket[string](0.001, 1, time.Minute) // refills far too slowly to matter
+
+	if !th.Allow("key") {
+		t.Fatal("first Allow should succeed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := th.Wait(ctx, "key", 1)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Wait returned %v, want context.DeadlineExceeded", err)
+	}
+}